@@ -3,7 +3,6 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"reflect"
 
 	"github.com/go-raptor/connector"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -35,32 +34,18 @@ func (c *PostgresConnector) Migrator() connector.Migrator {
 }
 
 func (c *PostgresConnector) Init() error {
-	val := reflect.ValueOf(c.config)
-
-	if val.Kind() != reflect.Struct {
-		return fmt.Errorf("input is not a struct")
+	cfg, err := connector.ResolveConfig(c.config)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config: %w", err)
 	}
 
-	hostField := val.FieldByName("Host")
-	portField := val.FieldByName("Port")
-	userField := val.FieldByName("Username")
-	passwordField := val.FieldByName("Password")
-	nameField := val.FieldByName("Name")
-
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
-		hostField.Interface().(string),
-		userField.Interface().(string),
-		passwordField.Interface().(string),
-		nameField.Interface().(string),
-		portField.Interface().(int),
-	)
-
-	configPgxPool, err := pgxpool.ParseConfig(dsn)
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
 	if err != nil {
 		return fmt.Errorf("failed to parse DSN: %w", err)
 	}
+	connector.ApplyPoolConfig(poolConfig, cfg)
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), configPgxPool)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -75,8 +60,15 @@ func (c *PostgresConnector) Init() error {
 	c.conn = db
 
 	c.migrator = NewPostgresMigrator(c.conn, c.migrations)
+	c.migrator.Table = cfg.MigrationsTable
+
+	if cfg.MigrationsDir != "" {
+		if err := c.migrator.UseSource(cfg.MigrationsDir, connector.FileSource(cfg.MigrationsDir)); err != nil {
+			return fmt.Errorf("failed to load migrations from %s: %w", cfg.MigrationsDir, err)
+		}
+	}
 
-	if err := c.migrator.Up(); err != nil {
+	if err := c.migrator.Up(context.Background()); err != nil {
 		return fmt.Errorf("failed to migrate: %w", err)
 	}
 
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/go-raptor/connector"
@@ -16,12 +17,21 @@ type Migration interface {
 }
 
 type Migrator interface {
-	Up() error
-	Down() error
-	UpTo(version string) error
-	DownTo(version string) error
-	Status() ([]MigrationStatus, error)
-	Version() (string, error)
+	Up(ctx context.Context) error
+	Down(ctx context.Context) error
+	UpTo(ctx context.Context, version string) error
+	DownTo(ctx context.Context, version string) error
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	Version(ctx context.Context) (string, error)
+	Create(name string) (string, error)
+	Force(ctx context.Context, version string) error
+	Drop(ctx context.Context) error
+	AllVersions() []connector.MigrationInfo
+	ExistingVersions(ctx context.Context) ([]connector.MigrationInfo, error)
+	Start(ctx context.Context, version string) error
+	Complete(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	ActiveSchema(ctx context.Context) (string, error)
 }
 
 type MigrationStatus struct {
@@ -29,51 +39,142 @@ type MigrationStatus struct {
 	Name       string
 	ExecutedAt *time.Time
 	IsApplied  bool
+	IsDirty    bool
 }
 
 type Migrations map[string]Migration
 
 type SchemaMigration struct {
-	Version    string    `bun:"version,pk"`
-	Name       string    `bun:"name,notnull"`
-	ExecutedAt time.Time `bun:"executed_at,notnull"`
+	Version    string     `bun:"version,pk"`
+	Name       string     `bun:"name,notnull"`
+	ExecutedAt *time.Time `bun:"executed_at"`
+	Dirty      bool       `bun:"dirty,notnull,default:false"`
 }
 
+// defaultMigrationsTable is used when PostgresMigrator.Table is left empty.
+const defaultMigrationsTable = "schema_migrations"
+
 func (SchemaMigration) TableName() string {
-	return "schema_migrations"
+	return defaultMigrationsTable
 }
 
 type PostgresMigrator struct {
-	db         *bun.DB
-	migrations Migrations
+	db            *bun.DB
+	migrations    Migrations
+	source        connector.MigrationSource
+	migrationsDir string
+
+	// Table overrides the name of the table used to track applied
+	// migrations. Empty means defaultMigrationsTable. Set it to let
+	// multiple apps share a database, each with its own bookkeeping table.
+	Table string
 }
 
 func NewPostgresMigrator(db *bun.DB, migrations Migrations) *PostgresMigrator {
+	if migrations == nil {
+		migrations = make(Migrations)
+	}
 	return &PostgresMigrator{
 		db:         db,
 		migrations: migrations,
 	}
 }
 
-func (pm *PostgresMigrator) createMigrationsTable() error {
+// table returns the configured migrations table name, falling back to
+// defaultMigrationsTable.
+func (pm *PostgresMigrator) table() string {
+	if pm.Table == "" {
+		return defaultMigrationsTable
+	}
+	return pm.Table
+}
+
+// UseSource discovers migrations from source (e.g. connector.FileSource or
+// connector.EmbedSource) and registers each as a Migration, so callers don't
+// have to hand-write an AddMigration call per file. dir is remembered so a
+// later Create can add new migration files next to the discovered ones.
+func (pm *PostgresMigrator) UseSource(dir string, source connector.MigrationSource) error {
+	entries, err := source.List()
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	for _, entry := range entries {
+		pm.migrations[entry.Version] = &sourceMigration{source: source, version: entry.Version, name: entry.Name}
+	}
+
+	pm.source = source
+	pm.migrationsDir = dir
+	return nil
+}
+
+// sourceMigration adapts a connector.MigrationSource entry to the bun
+// Migration interface, executing the raw SQL it reads for each direction.
+type sourceMigration struct {
+	source  connector.MigrationSource
+	version string
+	name    string
+}
+
+func (s *sourceMigration) Up(db *bun.DB) error   { return s.exec(db, "up") }
+func (s *sourceMigration) Down(db *bun.DB) error { return s.exec(db, "down") }
+func (s *sourceMigration) Name() string          { return s.name }
+
+func (s *sourceMigration) exec(db *bun.DB, direction string) error {
+	sql, err := s.source.Read(s.version, direction)
+	if err != nil {
+		return fmt.Errorf("failed to read %s migration %s: %w", direction, s.version, err)
+	}
+	_, err = db.ExecContext(context.Background(), string(sql))
+	return err
+}
+
+func (pm *PostgresMigrator) createMigrationsTable(ctx context.Context) error {
 	_, err := pm.db.NewCreateTable().
 		Model((*SchemaMigration)(nil)).
+		ModelTableExpr("?", bun.Ident(pm.table())).
 		IfNotExists().
-		Exec(context.Background())
+		Exec(ctx)
 	return err
 }
 
-func (pm *PostgresMigrator) Up() error {
-	if err := pm.createMigrationsTable(); err != nil {
+// checkDirty refuses to proceed if a previous run left a row marked dirty,
+// since that means a migration may have partially applied before failing.
+func (pm *PostgresMigrator) checkDirty(ctx context.Context) error {
+	dirty := make([]SchemaMigration, 0, 1)
+	err := pm.db.NewSelect().
+		Model(&dirty).
+		ModelTableExpr("?", bun.Ident(pm.table())).
+		Where("dirty = ?", true).
+		Order("version ASC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check dirty migrations: %w", err)
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	return &connector.ErrDirty{Version: dirty[0].Version, Name: dirty[0].Name}
+}
+
+func (pm *PostgresMigrator) Up(ctx context.Context) error {
+	if err := pm.createMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	if err := pm.checkDirty(ctx); err != nil {
 		return err
 	}
 
 	var executed []string
 	err := pm.db.NewSelect().
 		Model((*SchemaMigration)(nil)).
+		ModelTableExpr("?", bun.Ident(pm.table())).
 		Column("version").
 		Order("version ASC").
-		Scan(context.Background(), &executed)
+		Scan(ctx, &executed)
 	if err != nil {
 		return err
 	}
@@ -94,7 +195,14 @@ func (pm *PostgresMigrator) Up() error {
 	for _, version := range pending {
 		migration := pm.migrations[version]
 
-		tx, err := pm.db.BeginTx(context.Background(), nil)
+		if _, err := pm.db.NewInsert().
+			Model(&SchemaMigration{Version: version, Name: migration.Name(), Dirty: true}).
+			ModelTableExpr("?", bun.Ident(pm.table())).
+			Exec(ctx); err != nil {
+			return fmt.Errorf("failed to mark migration %s dirty: %w", version, err)
+		}
+
+		tx, err := pm.db.BeginTx(ctx, nil)
 		if err != nil {
 			return err
 		}
@@ -104,13 +212,13 @@ func (pm *PostgresMigrator) Up() error {
 			return err
 		}
 
-		_, err = tx.NewInsert().
-			Model(&SchemaMigration{
-				Version:    version,
-				Name:       migration.Name(),
-				ExecutedAt: time.Now(),
-			}).
-			Exec(context.Background())
+		executedAt := time.Now()
+		_, err = tx.NewUpdate().
+			Model(&SchemaMigration{ExecutedAt: &executedAt, Dirty: false}).
+			ModelTableExpr("?", bun.Ident(pm.table())).
+			Column("executed_at", "dirty").
+			Where("version = ?", version).
+			Exec(ctx)
 
 		if err != nil {
 			tx.Rollback()
@@ -125,26 +233,105 @@ func (pm *PostgresMigrator) Up() error {
 	return nil
 }
 
-func (pm *PostgresMigrator) Down() error {
+// Force clears the dirty flag on version without re-running its migration,
+// for use once an operator has manually verified or repaired the database
+// state after a failed migration.
+func (pm *PostgresMigrator) Force(ctx context.Context, version string) error {
+	res, err := pm.db.NewUpdate().
+		Model((*SchemaMigration)(nil)).
+		ModelTableExpr("?", bun.Ident(pm.table())).
+		Set("executed_at = COALESCE(executed_at, ?)", time.Now()).
+		Set("dirty = ?", false).
+		Where("version = ?", version).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to force version %s: %w", version, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to force version %s: %w", version, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("no %s row for version %s", pm.table(), version)
+	}
+
+	return nil
+}
+
+// Drop removes the migrations table entirely, so the next Up starts
+// bookkeeping from scratch. It does not touch any other tables.
+func (pm *PostgresMigrator) Drop(ctx context.Context) error {
+	_, err := pm.db.NewDropTable().
+		Model((*SchemaMigration)(nil)).
+		ModelTableExpr("?", bun.Ident(pm.table())).
+		IfExists().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drop migrations table: %w", err)
+	}
+	return nil
+}
+
+func (pm *PostgresMigrator) Down(ctx context.Context) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (pm *PostgresMigrator) UpTo(version string) error {
+func (pm *PostgresMigrator) UpTo(ctx context.Context, version string) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (pm *PostgresMigrator) DownTo(version string) error {
+func (pm *PostgresMigrator) DownTo(ctx context.Context, version string) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (pm *PostgresMigrator) Status() ([]connector.MigrationStatus, error) {
+func (pm *PostgresMigrator) Status(ctx context.Context) ([]connector.MigrationStatus, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
-func (pm *PostgresMigrator) Version() (string, error) {
+func (pm *PostgresMigrator) Version(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("not implemented")
 }
 
-func (pm *PostgresMigrator) Create(name string) error {
-	return fmt.Errorf("not implemented")
+// AllVersions returns every migration registered with the migrator,
+// regardless of whether it has been applied.
+func (pm *PostgresMigrator) AllVersions() []connector.MigrationInfo {
+	infos := make([]connector.MigrationInfo, 0, len(pm.migrations))
+	for version, migration := range pm.migrations {
+		infos = append(infos, connector.MigrationInfo{Version: version, Name: migration.Name()})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Version < infos[j].Version })
+	return infos
+}
+
+// ExistingVersions returns the subset of AllVersions that has already been
+// applied, as recorded in the migrations table.
+func (pm *PostgresMigrator) ExistingVersions(ctx context.Context) ([]connector.MigrationInfo, error) {
+	var migrations []SchemaMigration
+	err := pm.db.NewSelect().
+		Model(&migrations).
+		ModelTableExpr("?", bun.Ident(pm.table())).
+		Where("executed_at IS NOT NULL").
+		Order("version ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing migrations: %w", err)
+	}
+
+	infos := make([]connector.MigrationInfo, 0, len(migrations))
+	for _, sm := range migrations {
+		infos = append(infos, connector.MigrationInfo{Version: sm.Version, Name: sm.Name, ExecutedAt: sm.ExecutedAt})
+	}
+
+	return infos, nil
+}
+
+// Create writes a new timestamped up/down SQL pair into the directory
+// registered via UseSource and returns the generated version.
+func (pm *PostgresMigrator) Create(name string) (string, error) {
+	if pm.migrationsDir == "" {
+		return "", fmt.Errorf("migrator has no migrations directory configured, call UseSource first")
+	}
+	return connector.CreateMigrationFiles(pm.migrationsDir, name)
 }
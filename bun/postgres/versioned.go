@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-raptor/connector"
+	"github.com/uptrace/bun"
+)
+
+// VersionedMigration is an optional interface a Migration implements to
+// participate in zero-downtime expand/contract migrations driven by
+// PostgresMigrator.Start/Complete/Rollback, modeled on tools like pgroll:
+// new application versions read the view in the versioned schema Start
+// creates, while old ones keep using the physical table directly, until
+// Complete finalizes the physical change and drops that view. No view is
+// kept for earlier versions; after Complete every reader sees the new
+// layout of the physical table.
+//
+// This does not yet install backfill triggers for dual-writes during the
+// expand phase; connector.SetNotNull.Backfill covers reads of pre-existing
+// rows, but writes from old application code during the migration window
+// are not mirrored forward.
+type VersionedMigration interface {
+	Migration
+	Table() string
+	Operations() []connector.Operation
+}
+
+type pgrollStatus string
+
+const (
+	pgrollActive     pgrollStatus = "active"
+	pgrollCompleted  pgrollStatus = "completed"
+	pgrollRolledBack pgrollStatus = "rolled_back"
+)
+
+type PgrollMigration struct {
+	Version     string       `bun:"version,pk"`
+	Name        string       `bun:"name,notnull"`
+	SchemaName  string       `bun:"schema_name,notnull"`
+	Status      pgrollStatus `bun:"status,notnull"`
+	StartedAt   time.Time    `bun:"started_at,notnull,default:current_timestamp"`
+	CompletedAt *time.Time   `bun:"completed_at"`
+}
+
+func (PgrollMigration) TableName() string {
+	return "pgroll_migrations"
+}
+
+func (pm *PostgresMigrator) createPgrollTable(ctx context.Context) error {
+	if _, err := pm.db.NewCreateTable().
+		Model((*PgrollMigration)(nil)).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	// A unique index on a constant expression, filtered to active rows,
+	// guarantees at most one row can be active at a time.
+	_, err := pm.db.NewCreateIndex().
+		Model((*PgrollMigration)(nil)).
+		Index("pgroll_migrations_single_active").
+		ColumnExpr("(1)").
+		Where("status = ?", pgrollActive).
+		Unique().
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// viewColumns builds the SELECT list for a versioned view over
+// versioned.Table(): every existing physical column, passed through
+// unchanged, except the ones an Operation remaps (renamed, backfilled) or
+// hides (a pending DropColumn). Without this, a view built only from the
+// columns Operations() touches would silently omit every other column of
+// the table.
+func viewColumns(ctx context.Context, tx *bun.Tx, versioned VersionedMigration) ([]string, error) {
+	var existing []string
+	err := tx.NewSelect().
+		Table("information_schema.columns").
+		Column("column_name").
+		Where("table_name = ?", versioned.Table()).
+		OrderExpr("ordinal_position").
+		Scan(ctx, &existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of %s: %w", versioned.Table(), err)
+	}
+
+	dropped := make(map[string]bool)
+	renamed := make(map[string]string)
+	exprFor := make(map[string]string)
+	for _, op := range versioned.Operations() {
+		expr, name, ok := op.ViewColumn()
+		switch o := op.(type) {
+		case connector.DropColumn:
+			dropped[o.Column] = true
+		case connector.RenameColumn:
+			renamed[o.From] = name
+		default:
+			if ok {
+				exprFor[name] = expr
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(existing))
+	for _, col := range existing {
+		if dropped[col] {
+			continue
+		}
+		name := col
+		if to, ok := renamed[col]; ok {
+			name = to
+		}
+		expr := col
+		if e, ok := exprFor[name]; ok {
+			expr = e
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s", expr, name))
+	}
+
+	return columns, nil
+}
+
+func (pm *PostgresMigrator) activeMigration(ctx context.Context) (version, name, schemaName string, err error) {
+	var m PgrollMigration
+	err = pm.db.NewSelect().
+		Model(&m).
+		Where("status = ?", pgrollActive).
+		Limit(1).
+		Scan(ctx)
+
+	if err == sql.ErrNoRows {
+		return "", "", "", fmt.Errorf("no versioned migration is active")
+	}
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to query active migration: %w", err)
+	}
+	return m.Version, m.Name, m.SchemaName, nil
+}
+
+// Start begins a zero-downtime expand/contract migration: it creates a new
+// versioned schema containing a view over version's table that reflects
+// its Operations(), so new application code can read/write through it
+// while old code keeps using the table directly.
+func (pm *PostgresMigrator) Start(ctx context.Context, version string) error {
+	if err := pm.createPgrollTable(ctx); err != nil {
+		return fmt.Errorf("failed to create pgroll_migrations table: %w", err)
+	}
+
+	migration, ok := pm.migrations[version]
+	if !ok {
+		return fmt.Errorf("migration %s not found", version)
+	}
+	versioned, ok := migration.(VersionedMigration)
+	if !ok {
+		return fmt.Errorf("migration %s does not implement VersionedMigration", version)
+	}
+
+	activeCount, err := pm.db.NewSelect().Model((*PgrollMigration)(nil)).Where("status = ?", pgrollActive).Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for an active migration: %w", err)
+	}
+	if activeCount > 0 {
+		return fmt.Errorf("another versioned migration is already active")
+	}
+	generation, err := pm.db.NewSelect().Model((*PgrollMigration)(nil)).Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to determine schema generation: %w", err)
+	}
+	schemaName := fmt.Sprintf("%s_v%d", versioned.Table(), generation+1)
+
+	tx, err := pm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schemaName)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create versioned schema %s: %w", schemaName, err)
+	}
+
+	for _, op := range versioned.Operations() {
+		add, ok := op.(connector.AddColumn)
+		if !ok {
+			continue
+		}
+		sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", versioned.Table(), add.Column, add.Type)
+		if add.Default != "" {
+			sql += " DEFAULT " + add.Default
+		}
+		if _, err := tx.ExecContext(ctx, sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to add column %s.%s: %w", versioned.Table(), add.Column, err)
+		}
+	}
+
+	columns, err := viewColumns(ctx, &tx, versioned)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to build versioned view columns for %s: %w", versioned.Table(), err)
+	}
+
+	viewSQL := fmt.Sprintf("CREATE VIEW %s.%s AS SELECT %s FROM %s",
+		schemaName, versioned.Table(), strings.Join(columns, ", "), versioned.Table())
+	if _, err := tx.ExecContext(ctx, viewSQL); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create versioned view %s.%s: %w", schemaName, versioned.Table(), err)
+	}
+
+	if _, err := tx.NewInsert().
+		Model(&PgrollMigration{Version: version, Name: versioned.Name(), SchemaName: schemaName, Status: pgrollActive}).
+		Column("version", "name", "schema_name", "status").
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record active migration %s: %w", version, err)
+	}
+
+	return tx.Commit()
+}
+
+// Complete finalizes the active versioned migration: it applies the
+// outstanding physical changes (drop/rename/not-null), drops the versioned
+// view, and marks the migration completed.
+func (pm *PostgresMigrator) Complete(ctx context.Context) error {
+	version, _, schemaName, err := pm.activeMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	versioned, ok := pm.migrations[version].(VersionedMigration)
+	if !ok {
+		return fmt.Errorf("active migration %s does not implement VersionedMigration", version)
+	}
+
+	tx, err := pm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, op := range versioned.Operations() {
+		var sql string
+		switch o := op.(type) {
+		case connector.DropColumn:
+			sql = fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", o.Table, o.Column)
+		case connector.RenameColumn:
+			sql = fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", o.Table, o.From, o.To)
+		case connector.SetNotNull:
+			sql = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", o.Table, o.Column)
+		default:
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to finalize %T on %s: %w", op, versioned.Table(), err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s.%s", schemaName, versioned.Table())); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to drop versioned view: %w", err)
+	}
+
+	completedAt := time.Now()
+	if _, err := tx.NewUpdate().
+		Model(&PgrollMigration{Status: pgrollCompleted, CompletedAt: &completedAt}).
+		Column("status", "completed_at").
+		Where("version = ?", version).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to mark migration %s completed: %w", version, err)
+	}
+
+	return tx.Commit()
+}
+
+// Rollback abandons the active versioned migration: it drops the new
+// versioned schema without touching the physical table, and marks the
+// migration rolled back.
+func (pm *PostgresMigrator) Rollback(ctx context.Context) error {
+	version, _, schemaName, err := pm.activeMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schemaName)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to drop versioned schema %s: %w", schemaName, err)
+	}
+
+	if _, err := tx.NewUpdate().
+		Model(&PgrollMigration{Status: pgrollRolledBack}).
+		Column("status").
+		Where("version = ?", version).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to mark migration %s rolled back: %w", version, err)
+	}
+
+	return tx.Commit()
+}
+
+// ActiveSchema returns the versioned schema name of the currently active
+// expand/contract migration, or "" if none is active.
+func (pm *PostgresMigrator) ActiveSchema(ctx context.Context) (string, error) {
+	if err := pm.createPgrollTable(ctx); err != nil {
+		return "", fmt.Errorf("failed to create pgroll_migrations table: %w", err)
+	}
+
+	var m PgrollMigration
+	err := pm.db.NewSelect().
+		Model(&m).
+		Where("status = ?", pgrollActive).
+		Limit(1).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query active schema: %w", err)
+	}
+
+	return m.SchemaName, nil
+}
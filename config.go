@@ -0,0 +1,205 @@
+package connector
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config is a typed alternative to the legacy reflection-based config
+// structs accepted by NewPgxConnector and NewPostgresConnector. It covers
+// what those couldn't express: TLS, connection-pool tuning, a custom
+// application_name/search_path, and a per-tenant MigrationsTable.
+//
+// Only Host, User, Database are required; everything else has a driver-level
+// default. Port defaults to 5432.
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+
+	// SSLMode is passed through to the driver's sslmode connection
+	// parameter (e.g. "disable", "require", "verify-full"). Empty means
+	// "disable", matching the legacy reflection path's behavior.
+	SSLMode string
+	// SSLRootCert is the path to a CA bundle used to verify the server
+	// certificate, for "verify-ca"/"verify-full" SSLMode.
+	SSLRootCert string
+
+	SearchPath      string
+	ApplicationName string
+	ConnectTimeout  time.Duration
+
+	// MaxConns and MinConns bound the pgxpool pool size. Zero means the
+	// pool's own default.
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// StatementTimeout sets statement_timeout for every connection in the
+	// pool, guarding against runaway queries.
+	StatementTimeout time.Duration
+
+	// MigrationsDir, if set, is passed to UseSource(dir, FileSource(dir))
+	// during Init, same as the legacy reflection path's MigrationsDir field.
+	MigrationsDir string
+	// MigrationsTable overrides the name of the table used to track applied
+	// migrations. Empty means the migrator's own default
+	// ("schema_migrations"). Set it so multiple apps can share a database,
+	// each with its own bookkeeping table.
+	MigrationsTable string
+}
+
+// DSN renders c as a libpq-style connection string suitable for
+// pgxpool.ParseConfig.
+func (c Config) DSN() string {
+	port := c.Port
+	if port == 0 {
+		port = 5432
+	}
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	values := url.Values{}
+	values.Set("sslmode", sslMode)
+	if c.SSLRootCert != "" {
+		values.Set("sslrootcert", c.SSLRootCert)
+	}
+	if c.SearchPath != "" {
+		values.Set("search_path", c.SearchPath)
+	}
+	if c.ApplicationName != "" {
+		values.Set("application_name", c.ApplicationName)
+	}
+	if c.ConnectTimeout > 0 {
+		values.Set("connect_timeout", fmt.Sprintf("%d", int(c.ConnectTimeout.Seconds())))
+	}
+	if c.StatementTimeout > 0 {
+		values.Set("statement_timeout", fmt.Sprintf("%d", c.StatementTimeout.Milliseconds()))
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, port),
+		Path:     "/" + c.Database,
+		RawQuery: values.Encode(),
+	}
+	return u.String()
+}
+
+// ResolveConfig adapts raw into a Config. raw may already be a Config (or
+// *Config), in which case it's returned as-is. Otherwise ResolveConfig falls
+// back to the legacy reflection-based lookup (Host, Port, Username,
+// Password, Name fields, plus optional MigrationsDir and MigrationsTable
+// string fields) that NewPgxConnector and
+// NewPostgresConnector have always accepted, so existing callers don't have
+// to migrate to Config to keep working.
+func ResolveConfig(raw interface{}) (Config, error) {
+	switch v := raw.(type) {
+	case Config:
+		return v, nil
+	case *Config:
+		return *v, nil
+	}
+
+	val := reflect.ValueOf(raw)
+	if val.Kind() != reflect.Struct {
+		return Config{}, fmt.Errorf("input is not a struct")
+	}
+
+	host, ok := fieldString(val, "Host")
+	if !ok {
+		return Config{}, fmt.Errorf("input is missing a string Host field")
+	}
+	port, ok := fieldInt(val, "Port")
+	if !ok {
+		return Config{}, fmt.Errorf("input is missing an int Port field")
+	}
+	user, ok := fieldString(val, "Username")
+	if !ok {
+		return Config{}, fmt.Errorf("input is missing a string Username field")
+	}
+	password, ok := fieldString(val, "Password")
+	if !ok {
+		return Config{}, fmt.Errorf("input is missing a string Password field")
+	}
+	name, ok := fieldString(val, "Name")
+	if !ok {
+		return Config{}, fmt.Errorf("input is missing a string Name field")
+	}
+
+	cfg := Config{
+		Host:     host,
+		Port:     port,
+		User:     user,
+		Password: password,
+		Database: name,
+	}
+
+	if dir, ok := fieldString(val, "MigrationsDir"); ok {
+		cfg.MigrationsDir = dir
+	}
+	if table, ok := fieldString(val, "MigrationsTable"); ok {
+		cfg.MigrationsTable = table
+	}
+
+	return cfg, nil
+}
+
+// ApplyPoolConfig copies the pool-tuning fields of cfg onto poolConfig,
+// since they have no DSN representation pgxpool.ParseConfig understands.
+// Shared by the pgx and bun/postgres connectors, which both build their
+// pool from a pgxpool.Config.
+func ApplyPoolConfig(poolConfig *pgxpool.Config, cfg Config) {
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+}
+
+// fieldString reads a string-kinded field off val by name, reporting
+// ok=false (never panicking) if the field doesn't exist or isn't a string -
+// e.g. a legacy config struct that renamed or retyped the field.
+func fieldString(val reflect.Value, name string) (string, bool) {
+	field := val.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return "", false
+	}
+	return field.String(), true
+}
+
+// fieldInt reads an integer-kinded field off val by name, reporting
+// ok=false (never panicking) if the field doesn't exist or isn't an integer
+// - e.g. a legacy config struct declaring Port as a string. Any signed or
+// unsigned integer kind (int32 included) is accepted.
+func fieldInt(val reflect.Value, name string) (int, bool) {
+	field := val.FieldByName(name)
+	if !field.IsValid() {
+		return 0, false
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(field.Uint()), true
+	default:
+		return 0, false
+	}
+}
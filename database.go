@@ -1,6 +1,9 @@
 package connector
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type DatabaseConnector interface {
 	Init() error
@@ -9,12 +12,37 @@ type DatabaseConnector interface {
 }
 
 type Migrator interface {
-	Up() error
-	Down() error
-	UpTo(version string) error
-	DownTo(version string) error
-	Status() ([]MigrationStatus, error)
-	Version() (string, error)
+	Up(ctx context.Context) error
+	Down(ctx context.Context) error
+	UpTo(ctx context.Context, version string) error
+	DownTo(ctx context.Context, version string) error
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	Version(ctx context.Context) (string, error)
+	Create(name string) (version string, err error)
+	Force(ctx context.Context, version string) error
+	Drop(ctx context.Context) error
+
+	// AllVersions returns every migration registered with the Migrator,
+	// regardless of whether it has been applied.
+	AllVersions() []MigrationInfo
+	// ExistingVersions returns the subset of AllVersions that has already
+	// been applied, as recorded in the migrations table.
+	ExistingVersions(ctx context.Context) ([]MigrationInfo, error)
+
+	// Start begins a zero-downtime expand/contract migration for version:
+	// it creates a versioned schema containing a view over the migration's
+	// table that reflects its Operations(), so new application code can
+	// read/write through it while old code keeps using the table directly.
+	Start(ctx context.Context, version string) error
+	// Complete finalizes the active versioned migration: it applies the
+	// outstanding physical changes and drops the versioned view.
+	Complete(ctx context.Context) error
+	// Rollback abandons the active versioned migration: it drops the new
+	// versioned schema without touching the physical table.
+	Rollback(ctx context.Context) error
+	// ActiveSchema returns the versioned schema name of the currently
+	// active expand/contract migration, or "" if none is active.
+	ActiveSchema(ctx context.Context) (string, error)
 }
 
 type MigrationStatus struct {
@@ -22,4 +50,15 @@ type MigrationStatus struct {
 	Name       string
 	ExecutedAt *time.Time
 	IsApplied  bool
+	IsDirty    bool
+}
+
+// MigrationInfo is a migration's identity plus, if known, when it ran. It's
+// the lightweight counterpart to MigrationStatus for callers (e.g. a
+// migrate-list CLI command) that want the catalog without the joined
+// applied/pending view Status builds.
+type MigrationInfo struct {
+	Version    string
+	Name       string
+	ExecutedAt *time.Time
 }
@@ -0,0 +1,24 @@
+package connector
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDirty is returned by Up when a previous run left a row in
+// schema_migrations marked dirty, meaning the migration may have partially
+// applied before failing and the tracker can no longer be trusted. Operators
+// must inspect the database and call Migrator.Force once it's safe to
+// resume.
+type ErrDirty struct {
+	Version string
+	Name    string
+}
+
+func (e *ErrDirty) Error() string {
+	return fmt.Sprintf("migration %s (%s) is dirty, resolve manually and call Force", e.Version, e.Name)
+}
+
+// ErrLocked is returned when another process already holds the migrations
+// advisory lock and LockTimeout elapses before it's released.
+var ErrLocked = errors.New("connector: migrations are locked by another process")
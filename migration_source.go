@@ -0,0 +1,148 @@
+package connector
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// SourceEntry describes a single migration discovered by a MigrationSource,
+// independent of the backend (database driver) that will execute it.
+type SourceEntry struct {
+	Version string
+	Name    string
+}
+
+// MigrationSource loads migration file contents keyed by version and
+// direction ("up" or "down"), letting a Migrator discover migrations from
+// disk, an embed.FS, or any other byte-addressable store instead of
+// requiring every Migration to be hand-registered in code.
+type MigrationSource interface {
+	List() ([]SourceEntry, error)
+	Read(version, direction string) ([]byte, error)
+}
+
+var migrationFileRe = regexp.MustCompile(`^(\d{8}_\d{6})_([^.]+)\.(up|down)\.sql$`)
+
+type fileSource struct {
+	dir string
+}
+
+// FileSource returns a MigrationSource that reads versioned up/down SQL file
+// pairs from dir, e.g. "./migrations/20240101_150405_add_users.up.sql".
+func FileSource(dir string) MigrationSource {
+	return &fileSource{dir: dir}
+}
+
+func (s *fileSource) List() ([]SourceEntry, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	return listMigrationEntries(namesOf(entries))
+}
+
+func (s *fileSource) Read(version, direction string) ([]byte, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, version+"_*."+direction+".sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %s migration %s: %w", direction, version, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no %s migration found for version %s", direction, version)
+	}
+	return os.ReadFile(matches[0])
+}
+
+type embedSource struct {
+	fs   embed.FS
+	root string
+}
+
+// EmbedSource returns a MigrationSource backed by an embed.FS rooted at
+// root, so migrations can ship inside the application binary.
+func EmbedSource(fsys embed.FS, root string) MigrationSource {
+	return &embedSource{fs: fsys, root: root}
+}
+
+func (s *embedSource) List() ([]SourceEntry, error) {
+	entries, err := fs.ReadDir(s.fs, s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	return listMigrationEntries(namesOfDirEntries(entries))
+}
+
+func (s *embedSource) Read(version, direction string) ([]byte, error) {
+	entries, err := fs.ReadDir(s.fs, s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	for _, entry := range entries {
+		m := migrationFileRe.FindStringSubmatch(entry.Name())
+		if m == nil || m[1] != version || m[3] != direction {
+			continue
+		}
+		return s.fs.ReadFile(path.Join(s.root, entry.Name()))
+	}
+	return nil, fmt.Errorf("no %s migration found for version %s", direction, version)
+}
+
+func namesOf(entries []os.DirEntry) []string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names
+}
+
+func namesOfDirEntries(entries []fs.DirEntry) []string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names
+}
+
+func listMigrationEntries(filenames []string) ([]SourceEntry, error) {
+	seen := make(map[string]string)
+	for _, filename := range filenames {
+		m := migrationFileRe.FindStringSubmatch(filename)
+		if m == nil || m[3] != "up" {
+			continue
+		}
+		seen[m[1]] = m[2]
+	}
+
+	entries := make([]SourceEntry, 0, len(seen))
+	for version, name := range seen {
+		entries = append(entries, SourceEntry{Version: version, Name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Version < entries[j].Version })
+	return entries, nil
+}
+
+// CreateMigrationFiles writes a new timestamped up/down SQL pair into dir,
+// à la golang-migrate's "create" command, and returns the generated version.
+func CreateMigrationFiles(dir, name string) (version string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	version = time.Now().UTC().Format("20060102_150405")
+	base := filepath.Join(dir, fmt.Sprintf("%s_%s", version, name))
+
+	for _, direction := range []string{"up", "down"} {
+		path := fmt.Sprintf("%s.%s.sql", base, direction)
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("-- %s migration for %s\n", direction, name)), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write %s migration file: %w", direction, err)
+		}
+	}
+
+	return version, nil
+}
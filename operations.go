@@ -0,0 +1,48 @@
+package connector
+
+import "fmt"
+
+// Operation is one step of an expand/contract schema change against a
+// single table. A VersionedMigration's Operations() returns the
+// declarative list a Migrator's Start/Complete act on, so most
+// zero-downtime migrations don't need to hand-write the view-rewrite SQL
+// themselves. Operation is backend-agnostic; pgx and bun/postgres Migrators
+// both drive the same operation types through their own SQL executor.
+type Operation interface {
+	// ViewColumn returns the SELECT expression and exposed name this
+	// operation contributes to the versioned view, or ok=false if it
+	// doesn't add a column to the view (a drop).
+	ViewColumn() (expr, name string, ok bool)
+}
+
+type AddColumn struct {
+	Table, Column, Type, Default string
+}
+
+func (op AddColumn) ViewColumn() (string, string, bool) { return op.Column, op.Column, true }
+
+type DropColumn struct {
+	Table, Column string
+}
+
+func (op DropColumn) ViewColumn() (string, string, bool) { return "", "", false }
+
+type RenameColumn struct {
+	Table, From, To string
+}
+
+func (op RenameColumn) ViewColumn() (string, string, bool) { return op.From, op.To, true }
+
+// SetNotNull marks Column as NOT NULL once Complete runs. Backfill, if set,
+// is a SQL expression substituted for NULLs in the versioned view so old
+// rows read cleanly before the physical constraint is added.
+type SetNotNull struct {
+	Table, Column, Backfill string
+}
+
+func (op SetNotNull) ViewColumn() (string, string, bool) {
+	if op.Backfill == "" {
+		return op.Column, op.Column, true
+	}
+	return fmt.Sprintf("COALESCE(%s, %s)", op.Column, op.Backfill), op.Column, true
+}
@@ -3,7 +3,6 @@ package pgx
 import (
 	"context"
 	"fmt"
-	"reflect"
 
 	"github.com/go-raptor/connector"
 	"github.com/jackc/pgx/v5"
@@ -47,6 +46,7 @@ type PgxConnector struct {
 	config     interface{}
 	connType   ConnType
 	conn       *ConnWrapper
+	pool       *pgxpool.Pool
 	migrator   *PgxMigrator
 }
 
@@ -63,34 +63,22 @@ func (c *PgxConnector) Conn() any {
 }
 
 func (c *PgxConnector) Migrator() connector.Migrator {
+	if c.migrator == nil {
+		return nil
+	}
 	return c.migrator
 }
 
 func (c *PgxConnector) Init() error {
-	val := reflect.ValueOf(c.config)
-
-	if val.Kind() != reflect.Struct {
-		return fmt.Errorf("input is not a struct")
+	cfg, err := connector.ResolveConfig(c.config)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config: %w", err)
 	}
 
-	hostField := val.FieldByName("Host")
-	portField := val.FieldByName("Port")
-	userField := val.FieldByName("Username")
-	passwordField := val.FieldByName("Password")
-	nameField := val.FieldByName("Name")
-
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
-		hostField.Interface().(string),
-		userField.Interface().(string),
-		passwordField.Interface().(string),
-		nameField.Interface().(string),
-		portField.Interface().(int),
-	)
-
 	var wrapper *ConnWrapper
 
 	if c.connType == SingleConn {
-		conn, err := pgx.Connect(context.Background(), dsn)
+		conn, err := pgx.Connect(context.Background(), cfg.DSN())
 		if err != nil {
 			return fmt.Errorf("failed to create connection: %w", err)
 		}
@@ -99,11 +87,13 @@ func (c *PgxConnector) Init() error {
 			closeFunc:   conn.Close,
 		}
 	} else {
-		config, err := pgxpool.ParseConfig(dsn)
+		poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
 		if err != nil {
 			return fmt.Errorf("failed to parse DSN: %w", err)
 		}
-		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		connector.ApplyPoolConfig(poolConfig, cfg)
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 		if err != nil {
 			return fmt.Errorf("failed to create pool: %w", err)
 		}
@@ -111,6 +101,7 @@ func (c *PgxConnector) Init() error {
 			PgConnector: pool,
 			closeFunc:   wrapPoolClose(pool.Close),
 		}
+		c.pool = pool
 	}
 
 	if err := wrapper.Ping(context.Background()); err != nil {
@@ -118,9 +109,24 @@ func (c *PgxConnector) Init() error {
 	}
 
 	c.conn = wrapper
-	c.migrator = NewPgxMigrator(wrapper, c.migrations)
 
-	if err := c.migrator.Up(); err != nil {
+	if c.pool == nil {
+		if len(c.migrations) > 0 || cfg.MigrationsDir != "" {
+			return fmt.Errorf("migrations require PoolConn: PgxMigrator needs a *pgxpool.Pool, not a SingleConn connection")
+		}
+		return nil
+	}
+
+	c.migrator = NewPgxMigrator(c.pool, c.migrations)
+	c.migrator.Table = cfg.MigrationsTable
+
+	if cfg.MigrationsDir != "" {
+		if err := c.migrator.UseSource(cfg.MigrationsDir, connector.FileSource(cfg.MigrationsDir)); err != nil {
+			return fmt.Errorf("failed to load migrations from %s: %w", cfg.MigrationsDir, err)
+		}
+	}
+
+	if err := c.migrator.Up(context.Background()); err != nil {
 		return fmt.Errorf("failed to migrate: %w", err)
 	}
 
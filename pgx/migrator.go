@@ -2,38 +2,114 @@ package pgx
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/crc64"
 	"sort"
 	"time"
 
 	"github.com/go-raptor/connector"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// defaultMigrationsTable is used when PgxMigrator.Table is left empty.
+const defaultMigrationsTable = "schema_migrations"
+
+// pgLockNotAvailable is the SQLSTATE Postgres returns when lock_timeout
+// expires while waiting on pg_advisory_lock.
+const pgLockNotAvailable = "55P03"
+
 type Migration interface {
 	Up(tx pgx.Tx) error
 	Down(tx pgx.Tx) error
 	Name() string
 }
 
+// ConnMigration is an optional interface a Migration implements alongside
+// Migration when it also sets MigrationOpts.DisableTransaction, since such
+// migrations run on a bare connection rather than inside a pgx.Tx.
+type ConnMigration interface {
+	UpConn(conn *pgx.Conn) error
+	DownConn(conn *pgx.Conn) error
+}
+
+// MigrationOpts customizes how a single migration is executed.
+type MigrationOpts struct {
+	// DisableTransaction runs the migration outside BeginTx, on a
+	// dedicated connection, for DDL Postgres cannot run inside a
+	// transaction (e.g. CREATE INDEX CONCURRENTLY, ALTER TYPE ... ADD
+	// VALUE, VACUUM). The Migration must also implement ConnMigration.
+	DisableTransaction bool
+	StatementTimeout   time.Duration
+	LockTimeout        time.Duration
+}
+
+// MigrationOptions is an optional interface a Migration can implement to
+// customize its execution; it's type-asserted at runtime so existing
+// migrations that don't implement it keep running exactly as before.
+type MigrationOptions interface {
+	Options() MigrationOpts
+}
+
+func migrationOptsFor(migration Migration) MigrationOpts {
+	if m, ok := migration.(MigrationOptions); ok {
+		return m.Options()
+	}
+	return MigrationOpts{}
+}
+
 type Migrations map[string]Migration
 
 type PgxMigrator struct {
-	pool       *pgxpool.Pool
-	migrations Migrations
+	pool          *pgxpool.Pool
+	migrations    Migrations
+	source        connector.MigrationSource
+	migrationsDir string
+
+	// LockTimeout bounds how long Up/Down/UpTo/DownTo wait to acquire the
+	// migrations advisory lock before giving up with connector.ErrLocked.
+	// Zero means wait indefinitely.
+	LockTimeout time.Duration
+
+	// Table overrides the name of the table used to track applied
+	// migrations. Empty means defaultMigrationsTable. Set it to let
+	// multiple apps share a database, each with its own bookkeeping table.
+	Table string
+}
+
+// table returns the configured migrations table name, falling back to
+// defaultMigrationsTable.
+func (m *PgxMigrator) table() string {
+	if m.Table == "" {
+		return defaultMigrationsTable
+	}
+	return m.Table
+}
+
+// lockID derives a stable advisory-lock key from the migrations table
+// name, so every instance of an app hashes to the same lock regardless of
+// which process acquires it first, and apps using different tables don't
+// contend on the same lock.
+func (m *PgxMigrator) lockID() int64 {
+	return int64(crc64.Checksum([]byte(m.table()), crc64.MakeTable(crc64.ISO)))
 }
 
 type SchemaMigration struct {
 	Version    string
 	Name       string
-	ExecutedAt time.Time
+	ExecutedAt *time.Time
+	Dirty      bool
 }
 
-func NewPgxMigrator(pool *pgxpool.Pool) *PgxMigrator {
+func NewPgxMigrator(pool *pgxpool.Pool, migrations Migrations) *PgxMigrator {
+	if migrations == nil {
+		migrations = make(Migrations)
+	}
 	return &PgxMigrator{
 		pool:       pool,
-		migrations: make(Migrations),
+		migrations: migrations,
 	}
 }
 
@@ -41,26 +117,131 @@ func (m *PgxMigrator) AddMigration(version string, migration Migration) {
 	m.migrations[version] = migration
 }
 
+// UseSource discovers migrations from source (e.g. connector.FileSource or
+// connector.EmbedSource) and registers each as a Migration, so callers don't
+// have to hand-write an AddMigration call per file. dir is remembered so a
+// later Create can add new migration files next to the discovered ones.
+func (m *PgxMigrator) UseSource(dir string, source connector.MigrationSource) error {
+	entries, err := source.List()
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	for _, entry := range entries {
+		m.migrations[entry.Version] = &sourceMigration{source: source, version: entry.Version, name: entry.Name}
+	}
+
+	m.source = source
+	m.migrationsDir = dir
+	return nil
+}
+
+// Create writes a new timestamped up/down SQL pair into the directory
+// registered via UseSource and returns the generated version.
+func (m *PgxMigrator) Create(name string) (string, error) {
+	if m.migrationsDir == "" {
+		return "", fmt.Errorf("migrator has no migrations directory configured, call UseSource first")
+	}
+	return connector.CreateMigrationFiles(m.migrationsDir, name)
+}
+
+// sourceMigration adapts a connector.MigrationSource entry to the pgx
+// Migration interface, executing the raw SQL it reads for each direction.
+type sourceMigration struct {
+	source  connector.MigrationSource
+	version string
+	name    string
+}
+
+func (s *sourceMigration) Up(tx pgx.Tx) error   { return s.exec(tx, "up") }
+func (s *sourceMigration) Down(tx pgx.Tx) error { return s.exec(tx, "down") }
+func (s *sourceMigration) Name() string        { return s.name }
+
+func (s *sourceMigration) exec(tx pgx.Tx, direction string) error {
+	sql, err := s.source.Read(s.version, direction)
+	if err != nil {
+		return fmt.Errorf("failed to read %s migration %s: %w", direction, s.version, err)
+	}
+	_, err = tx.Exec(context.Background(), string(sql))
+	return err
+}
+
 func (m *PgxMigrator) createMigrationsTable(ctx context.Context) error {
-	sql := `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
+	sql := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
 			version VARCHAR(255) PRIMARY KEY,
 			name VARCHAR(255) NOT NULL,
-			executed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)`
+			executed_at TIMESTAMP,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE
+		)`, m.table())
 
 	_, err := m.pool.Exec(ctx, sql)
 	return err
 }
 
-func (m *PgxMigrator) Up() error {
-	ctx := context.Background()
+// checkDirty refuses to proceed if a previous run left a row marked dirty,
+// since that means a migration may have partially applied before failing.
+func (m *PgxMigrator) checkDirty(ctx context.Context) error {
+	var version, name string
+	err := m.pool.QueryRow(ctx,
+		fmt.Sprintf("SELECT version, name FROM %s WHERE dirty = TRUE ORDER BY version ASC LIMIT 1", m.table()),
+	).Scan(&version, &name)
+
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check dirty migrations: %w", err)
+	}
+
+	return &connector.ErrDirty{Version: version, Name: name}
+}
+
+// withLock acquires the migrations advisory lock on a dedicated connection
+// (via pool.Acquire, so pgxpool can't rotate it out from under us) and runs
+// fn while holding it, guarding against concurrent deploys racing to run the
+// same migrations. The lock is released via defer even if fn panics.
+func (m *PgxMigrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire a dedicated connection: %w", err)
+	}
+	defer conn.Release()
+
+	if m.LockTimeout > 0 {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("SET lock_timeout = '%dms'", m.LockTimeout.Milliseconds())); err != nil {
+			return fmt.Errorf("failed to set lock timeout: %w", err)
+		}
+		defer conn.Exec(context.Background(), "RESET lock_timeout")
+	}
+
+	lockID := m.lockID()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgLockNotAvailable {
+			return connector.ErrLocked
+		}
+		return fmt.Errorf("failed to acquire migrations lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	return fn(ctx)
+}
+
+func (m *PgxMigrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, m.up)
+}
 
+func (m *PgxMigrator) up(ctx context.Context) error {
 	if err := m.createMigrationsTable(ctx); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
-	rows, err := m.pool.Query(ctx, "SELECT version FROM schema_migrations ORDER BY version ASC")
+	if err := m.checkDirty(ctx); err != nil {
+		return err
+	}
+
+	rows, err := m.pool.Query(ctx, fmt.Sprintf("SELECT version FROM %s ORDER BY version ASC", m.table()))
 	if err != nil {
 		return fmt.Errorf("failed to query migrations: %w", err)
 	}
@@ -85,39 +266,146 @@ func (m *PgxMigrator) Up() error {
 
 	for _, version := range pending {
 		migration := m.migrations[version]
+		opts := migrationOptsFor(migration)
 
-		tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to start transaction: %w", err)
+		if _, err := m.pool.Exec(ctx,
+			fmt.Sprintf("INSERT INTO %s (version, name, dirty) VALUES ($1, $2, TRUE)", m.table()),
+			version, migration.Name(),
+		); err != nil {
+			return fmt.Errorf("failed to mark migration %s dirty: %w", version, err)
 		}
 
-		if err := migration.Up(tx); err != nil {
-			tx.Rollback(ctx)
-			return fmt.Errorf("failed to execute migration %s: %w", version, err)
+		if opts.DisableTransaction {
+			if err := m.runWithoutTransaction(ctx, migration, opts, version); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := m.runInTransaction(ctx, migration, opts, version); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO schema_migrations (version, name, executed_at) VALUES ($1, $2, $3)",
-			version, migration.Name(), time.Now(),
-		)
-		if err != nil {
+// runInTransaction executes migration inside a BeginTx/Commit pair, the
+// default for migrations that don't opt out via MigrationOptions.
+func (m *PgxMigrator) runInTransaction(ctx context.Context, migration Migration, opts MigrationOpts, version string) error {
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if opts.StatementTimeout > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = '%dms'", opts.StatementTimeout.Milliseconds())); err != nil {
 			tx.Rollback(ctx)
-			return fmt.Errorf("failed to record migration %s: %w", version, err)
+			return fmt.Errorf("failed to set statement timeout for migration %s: %w", version, err)
+		}
+	}
+
+	if err := migration.Up(tx); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to execute migration %s: %w", version, err)
+	}
+
+	_, err = tx.Exec(ctx,
+		fmt.Sprintf("UPDATE %s SET executed_at = $2, dirty = FALSE WHERE version = $1", m.table()),
+		version, time.Now(),
+	)
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", version, err)
+	}
+
+	return nil
+}
+
+// runWithoutTransaction executes migration on a dedicated connection
+// outside any transaction, for DDL Postgres refuses to run transactionally
+// (CREATE INDEX CONCURRENTLY, ALTER TYPE ... ADD VALUE, VACUUM). The
+// migrations-table row stays dirty until the migration and its bookkeeping
+// both succeed, so a mid-flight failure is recoverable via Force.
+func (m *PgxMigrator) runWithoutTransaction(ctx context.Context, migration Migration, opts MigrationOpts, version string) error {
+	connMigration, ok := migration.(ConnMigration)
+	if !ok {
+		return fmt.Errorf("migration %s disables transactions but does not implement ConnMigration", version)
+	}
+
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire a dedicated connection for migration %s: %w", version, err)
+	}
+	defer conn.Release()
+
+	if opts.StatementTimeout > 0 {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = '%dms'", opts.StatementTimeout.Milliseconds())); err != nil {
+			return fmt.Errorf("failed to set statement timeout for migration %s: %w", version, err)
 		}
+		defer conn.Exec(context.Background(), "RESET statement_timeout")
+	}
 
-		if err := tx.Commit(ctx); err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+	if opts.LockTimeout > 0 {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("SET lock_timeout = '%dms'", opts.LockTimeout.Milliseconds())); err != nil {
+			return fmt.Errorf("failed to set lock timeout for migration %s: %w", version, err)
 		}
+		defer conn.Exec(context.Background(), "RESET lock_timeout")
+	}
+
+	if err := connMigration.UpConn(conn.Conn()); err != nil {
+		return fmt.Errorf("failed to execute migration %s: %w", version, err)
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		fmt.Sprintf("UPDATE %s SET executed_at = $2, dirty = FALSE WHERE version = $1", m.table()),
+		version, time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+
+	return nil
+}
+
+// Force clears the dirty flag on version without re-running its migration,
+// for use once an operator has manually verified or repaired the database
+// state after a failed migration.
+func (m *PgxMigrator) Force(ctx context.Context, version string) error {
+	tag, err := m.pool.Exec(ctx,
+		fmt.Sprintf("UPDATE %s SET executed_at = COALESCE(executed_at, $2), dirty = FALSE WHERE version = $1", m.table()),
+		version, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to force version %s: %w", version, err)
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no %s row for version %s", m.table(), version)
+	}
+
+	return nil
+}
 
+// Drop removes the migrations table entirely, so the next Up starts
+// bookkeeping from scratch. It does not touch any other tables.
+func (m *PgxMigrator) Drop(ctx context.Context) error {
+	_, err := m.pool.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", m.table()))
+	if err != nil {
+		return fmt.Errorf("failed to drop migrations table: %w", err)
+	}
 	return nil
 }
 
-func (m *PgxMigrator) Down() error {
-	ctx := context.Background()
+func (m *PgxMigrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, m.down)
+}
 
+func (m *PgxMigrator) down(ctx context.Context) error {
 	rows, err := m.pool.Query(ctx,
-		"SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1",
+		fmt.Sprintf("SELECT version FROM %s ORDER BY version DESC LIMIT 1", m.table()),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to query last migration: %w", err)
@@ -138,17 +426,34 @@ func (m *PgxMigrator) Down() error {
 		return fmt.Errorf("migration %s not found", version)
 	}
 
+	opts := migrationOptsFor(migration)
+	if opts.DisableTransaction {
+		return m.runDownWithoutTransaction(ctx, migration, opts, version)
+	}
+	return m.runDownInTransaction(ctx, migration, opts, version)
+}
+
+// runDownInTransaction executes migration.Down inside a BeginTx/Commit pair,
+// the down-direction counterpart of runInTransaction.
+func (m *PgxMigrator) runDownInTransaction(ctx context.Context, migration Migration, opts MigrationOpts, version string) error {
 	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
+	if opts.StatementTimeout > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = '%dms'", opts.StatementTimeout.Milliseconds())); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to set statement timeout for migration %s: %w", version, err)
+		}
+	}
+
 	if err := migration.Down(tx); err != nil {
 		tx.Rollback(ctx)
 		return fmt.Errorf("failed to roll back migration %s: %w", version, err)
 	}
 
-	_, err = tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+	_, err = tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.table()), version)
 	if err != nil {
 		tx.Rollback(ctx)
 		return fmt.Errorf("failed to delete migration record %s: %w", version, err)
@@ -161,46 +466,121 @@ func (m *PgxMigrator) Down() error {
 	return nil
 }
 
-func (m *PgxMigrator) UpTo(version string) error {
-	ctx := context.Background()
+// runDownWithoutTransaction executes migration.DownConn on a dedicated
+// connection outside any transaction, the down-direction counterpart of
+// runWithoutTransaction, for migrations whose Up disables transactions
+// (e.g. CREATE INDEX CONCURRENTLY) and whose Down must match (DROP INDEX
+// CONCURRENTLY).
+func (m *PgxMigrator) runDownWithoutTransaction(ctx context.Context, migration Migration, opts MigrationOpts, version string) error {
+	connMigration, ok := migration.(ConnMigration)
+	if !ok {
+		return fmt.Errorf("migration %s disables transactions but does not implement ConnMigration", version)
+	}
+
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire a dedicated connection for migration %s: %w", version, err)
+	}
+	defer conn.Release()
+
+	if opts.StatementTimeout > 0 {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = '%dms'", opts.StatementTimeout.Milliseconds())); err != nil {
+			return fmt.Errorf("failed to set statement timeout for migration %s: %w", version, err)
+		}
+		defer conn.Exec(context.Background(), "RESET statement_timeout")
+	}
+
+	if opts.LockTimeout > 0 {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("SET lock_timeout = '%dms'", opts.LockTimeout.Milliseconds())); err != nil {
+			return fmt.Errorf("failed to set lock timeout for migration %s: %w", version, err)
+		}
+		defer conn.Exec(context.Background(), "RESET lock_timeout")
+	}
+
+	if err := connMigration.DownConn(conn.Conn()); err != nil {
+		return fmt.Errorf("failed to roll back migration %s: %w", version, err)
+	}
+
+	if _, err := m.pool.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.table()), version); err != nil {
+		return fmt.Errorf("failed to delete migration record %s: %w", version, err)
+	}
+
+	return nil
+}
+
+func (m *PgxMigrator) UpTo(ctx context.Context, version string) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		return m.upTo(ctx, version)
+	})
+}
 
+func (m *PgxMigrator) upTo(ctx context.Context, version string) error {
 	if err := m.createMigrationsTable(ctx); err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
-	var versions []string
+	if err := m.checkDirty(ctx); err != nil {
+		return err
+	}
+
+	rows, err := m.pool.Query(ctx, fmt.Sprintf("SELECT version FROM %s ORDER BY version ASC", m.table()))
+	if err != nil {
+		return fmt.Errorf("failed to query migrations: %w", err)
+	}
+	defer rows.Close()
+
+	executed := make(map[string]bool)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		executed[v] = true
+	}
+
+	var pending []string
 	for v := range m.migrations {
-		if v <= version {
-			versions = append(versions, v)
+		if v <= version && !executed[v] {
+			pending = append(pending, v)
 		}
 	}
-	sort.Strings(versions)
+	sort.Strings(pending)
 
-	for _, v := range versions {
+	for _, v := range pending {
 		migration := m.migrations[v]
-		tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to start transaction: %w", err)
+		opts := migrationOptsFor(migration)
+
+		if _, err := m.pool.Exec(ctx,
+			fmt.Sprintf("INSERT INTO %s (version, name, dirty) VALUES ($1, $2, TRUE)", m.table()),
+			v, migration.Name(),
+		); err != nil {
+			return fmt.Errorf("failed to mark migration %s dirty: %w", v, err)
 		}
 
-		if err := migration.Up(tx); err != nil {
-			tx.Rollback(ctx)
-			return fmt.Errorf("failed to execute migration %s: %w", v, err)
+		if opts.DisableTransaction {
+			if err := m.runWithoutTransaction(ctx, migration, opts, v); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if err := tx.Commit(ctx); err != nil {
-			return fmt.Errorf("failed to commit migration %s: %w", v, err)
+		if err := m.runInTransaction(ctx, migration, opts, v); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (m *PgxMigrator) DownTo(version string) error {
-	ctx := context.Background()
+func (m *PgxMigrator) DownTo(ctx context.Context, version string) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		return m.downTo(ctx, version)
+	})
+}
 
+func (m *PgxMigrator) downTo(ctx context.Context, version string) error {
 	rows, err := m.pool.Query(ctx,
-		"SELECT version FROM schema_migrations WHERE version > $1 ORDER BY version DESC",
+		fmt.Sprintf("SELECT version FROM %s WHERE version > $1 ORDER BY version DESC", m.table()),
 		version,
 	)
 	if err != nil {
@@ -218,30 +598,30 @@ func (m *PgxMigrator) DownTo(version string) error {
 	}
 
 	for _, v := range versions {
-		migration := m.migrations[v]
-		tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to start transaction: %w", err)
+		migration, exists := m.migrations[v]
+		if !exists {
+			return fmt.Errorf("migration %s not found", v)
 		}
 
-		if err := migration.Down(tx); err != nil {
-			tx.Rollback(ctx)
-			return fmt.Errorf("failed to roll back migration %s: %w", v, err)
+		opts := migrationOptsFor(migration)
+		if opts.DisableTransaction {
+			if err := m.runDownWithoutTransaction(ctx, migration, opts, v); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if err := tx.Commit(ctx); err != nil {
-			return fmt.Errorf("failed to commit rollback of migration %s: %w", v, err)
+		if err := m.runDownInTransaction(ctx, migration, opts, v); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (m *PgxMigrator) Status() ([]connector.MigrationStatus, error) {
-	ctx := context.Background()
-
+func (m *PgxMigrator) Status(ctx context.Context) ([]connector.MigrationStatus, error) {
 	rows, err := m.pool.Query(ctx,
-		"SELECT version, name, executed_at FROM schema_migrations ORDER BY version ASC",
+		fmt.Sprintf("SELECT version, name, executed_at, dirty FROM %s ORDER BY version ASC", m.table()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query migrations: %w", err)
@@ -251,12 +631,12 @@ func (m *PgxMigrator) Status() ([]connector.MigrationStatus, error) {
 	executed := make(map[string]*connector.MigrationStatus)
 	for rows.Next() {
 		var status connector.MigrationStatus
-		var executedAt time.Time
-		if err := rows.Scan(&status.Version, &status.Name, &executedAt); err != nil {
+		var executedAt *time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &executedAt, &status.IsDirty); err != nil {
 			return nil, fmt.Errorf("failed to scan migration status: %w", err)
 		}
-		status.ExecutedAt = &executedAt
-		status.IsApplied = true
+		status.ExecutedAt = executedAt
+		status.IsApplied = executedAt != nil
 		executed[status.Version] = &status
 	}
 
@@ -281,12 +661,10 @@ func (m *PgxMigrator) Status() ([]connector.MigrationStatus, error) {
 	return statuses, nil
 }
 
-func (m *PgxMigrator) Version() (string, error) {
-	ctx := context.Background()
-
+func (m *PgxMigrator) Version(ctx context.Context) (string, error) {
 	var version string
 	err := m.pool.QueryRow(ctx,
-		"SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1",
+		fmt.Sprintf("SELECT version FROM %s ORDER BY version DESC LIMIT 1", m.table()),
 	).Scan(&version)
 
 	if err == pgx.ErrNoRows {
@@ -298,3 +676,40 @@ func (m *PgxMigrator) Version() (string, error) {
 
 	return version, nil
 }
+
+// AllVersions returns every migration registered with the migrator,
+// regardless of whether it has been applied.
+func (m *PgxMigrator) AllVersions() []connector.MigrationInfo {
+	infos := make([]connector.MigrationInfo, 0, len(m.migrations))
+	for version, migration := range m.migrations {
+		infos = append(infos, connector.MigrationInfo{Version: version, Name: migration.Name()})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Version < infos[j].Version })
+	return infos
+}
+
+// ExistingVersions returns the subset of AllVersions that has already been
+// applied, as recorded in the migrations table.
+func (m *PgxMigrator) ExistingVersions(ctx context.Context) ([]connector.MigrationInfo, error) {
+	rows, err := m.pool.Query(ctx,
+		fmt.Sprintf("SELECT version, name, executed_at FROM %s WHERE executed_at IS NOT NULL ORDER BY version ASC", m.table()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var infos []connector.MigrationInfo
+	for rows.Next() {
+		var info connector.MigrationInfo
+		var executedAt time.Time
+		if err := rows.Scan(&info.Version, &info.Name, &executedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		info.ExecutedAt = &executedAt
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
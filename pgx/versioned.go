@@ -0,0 +1,345 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-raptor/connector"
+	"github.com/jackc/pgx/v5"
+)
+
+// Operation and its implementations are aliases of the backend-agnostic
+// types in the connector package, so existing pgx.AddColumn{...} call
+// sites keep working unchanged now that bun/postgres drives the same
+// operation types.
+type (
+	Operation    = connector.Operation
+	AddColumn    = connector.AddColumn
+	DropColumn   = connector.DropColumn
+	RenameColumn = connector.RenameColumn
+	SetNotNull   = connector.SetNotNull
+)
+
+// VersionedMigration is an optional interface a Migration implements to
+// participate in zero-downtime expand/contract migrations driven by
+// PgxMigrator.Start/Complete/Rollback, modeled on tools like pgroll: new
+// application versions read the view in the versioned schema Start
+// creates, while old ones keep using the physical table directly, until
+// Complete finalizes the physical change and drops that view. No view is
+// kept for earlier versions; after Complete every reader sees the new
+// layout of the physical table.
+//
+// This does not yet install backfill triggers for dual-writes during the
+// expand phase; SetNotNull.Backfill covers reads of pre-existing rows, but
+// writes from old application code during the migration window are not
+// mirrored forward.
+type VersionedMigration interface {
+	Migration
+	Table() string
+	Operations() []Operation
+}
+
+type pgrollStatus string
+
+const (
+	pgrollActive     pgrollStatus = "active"
+	pgrollCompleted  pgrollStatus = "completed"
+	pgrollRolledBack pgrollStatus = "rolled_back"
+)
+
+func (m *PgxMigrator) createPgrollTable(ctx context.Context) error {
+	if _, err := m.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pgroll_migrations (
+			version      VARCHAR(255) PRIMARY KEY,
+			name         VARCHAR(255) NOT NULL,
+			schema_name  VARCHAR(255) NOT NULL,
+			status       VARCHAR(32) NOT NULL,
+			started_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP
+		)`); err != nil {
+		return err
+	}
+
+	// A unique index on a constant expression, filtered to active rows,
+	// guarantees at most one row can be active at a time.
+	_, err := m.pool.Exec(ctx, `
+		CREATE UNIQUE INDEX IF NOT EXISTS pgroll_migrations_single_active
+			ON pgroll_migrations ((1))
+			WHERE status = 'active'`)
+	return err
+}
+
+// viewColumns builds the SELECT list for a versioned view over
+// versioned.Table(): every existing physical column, passed through
+// unchanged, except the ones an Operation remaps (renamed, backfilled) or
+// hides (a pending DropColumn). Without this, a view built only from the
+// columns Operations() touches would silently omit every other column of
+// the table.
+func viewColumns(ctx context.Context, tx pgx.Tx, versioned VersionedMigration) ([]string, error) {
+	rows, err := tx.Query(ctx,
+		"SELECT column_name FROM information_schema.columns WHERE table_name = $1 ORDER BY ordinal_position",
+		versioned.Table(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of %s: %w", versioned.Table(), err)
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan column name: %w", err)
+		}
+		existing = append(existing, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dropped := make(map[string]bool)
+	renamed := make(map[string]string)
+	exprFor := make(map[string]string)
+	for _, op := range versioned.Operations() {
+		expr, name, ok := op.ViewColumn()
+		switch o := op.(type) {
+		case DropColumn:
+			dropped[o.Column] = true
+		case RenameColumn:
+			renamed[o.From] = name
+		default:
+			if ok {
+				exprFor[name] = expr
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(existing))
+	for _, col := range existing {
+		if dropped[col] {
+			continue
+		}
+		name := col
+		if to, ok := renamed[col]; ok {
+			name = to
+		}
+		expr := col
+		if e, ok := exprFor[name]; ok {
+			expr = e
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s", expr, name))
+	}
+
+	return columns, nil
+}
+
+func (m *PgxMigrator) activeMigration(ctx context.Context) (version, name, schemaName string, err error) {
+	err = m.pool.QueryRow(ctx,
+		"SELECT version, name, schema_name FROM pgroll_migrations WHERE status = 'active' LIMIT 1",
+	).Scan(&version, &name, &schemaName)
+
+	if err == pgx.ErrNoRows {
+		return "", "", "", fmt.Errorf("no versioned migration is active")
+	}
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to query active migration: %w", err)
+	}
+	return version, name, schemaName, nil
+}
+
+// Start begins a zero-downtime expand/contract migration: it creates a new
+// versioned schema containing a view over version's table that reflects
+// its Operations(), so new application code can read/write through it
+// while old code keeps using the table directly.
+func (m *PgxMigrator) Start(ctx context.Context, version string) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		return m.start(ctx, version)
+	})
+}
+
+func (m *PgxMigrator) start(ctx context.Context, version string) error {
+	if err := m.createPgrollTable(ctx); err != nil {
+		return fmt.Errorf("failed to create pgroll_migrations table: %w", err)
+	}
+
+	migration, ok := m.migrations[version]
+	if !ok {
+		return fmt.Errorf("migration %s not found", version)
+	}
+	versioned, ok := migration.(VersionedMigration)
+	if !ok {
+		return fmt.Errorf("migration %s does not implement VersionedMigration", version)
+	}
+
+	var activeCount, generation int
+	if err := m.pool.QueryRow(ctx, "SELECT COUNT(*) FROM pgroll_migrations WHERE status = 'active'").Scan(&activeCount); err != nil {
+		return fmt.Errorf("failed to check for an active migration: %w", err)
+	}
+	if activeCount > 0 {
+		return fmt.Errorf("another versioned migration is already active")
+	}
+	if err := m.pool.QueryRow(ctx, "SELECT COUNT(*) FROM pgroll_migrations").Scan(&generation); err != nil {
+		return fmt.Errorf("failed to determine schema generation: %w", err)
+	}
+	schemaName := fmt.Sprintf("%s_v%d", versioned.Table(), generation+1)
+
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schemaName)); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to create versioned schema %s: %w", schemaName, err)
+	}
+
+	for _, op := range versioned.Operations() {
+		add, ok := op.(AddColumn)
+		if !ok {
+			continue
+		}
+		sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", versioned.Table(), add.Column, add.Type)
+		if add.Default != "" {
+			sql += " DEFAULT " + add.Default
+		}
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to add column %s.%s: %w", versioned.Table(), add.Column, err)
+		}
+	}
+
+	columns, err := viewColumns(ctx, tx, versioned)
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to build versioned view columns for %s: %w", versioned.Table(), err)
+	}
+
+	viewSQL := fmt.Sprintf("CREATE VIEW %s.%s AS SELECT %s FROM %s",
+		schemaName, versioned.Table(), strings.Join(columns, ", "), versioned.Table())
+	if _, err := tx.Exec(ctx, viewSQL); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to create versioned view %s.%s: %w", schemaName, versioned.Table(), err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO pgroll_migrations (version, name, schema_name, status) VALUES ($1, $2, $3, $4)",
+		version, versioned.Name(), schemaName, pgrollActive,
+	); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to record active migration %s: %w", version, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Complete finalizes the active versioned migration: it applies the
+// outstanding physical changes (drop/rename/not-null), drops the versioned
+// view, and marks the migration completed.
+func (m *PgxMigrator) Complete(ctx context.Context) error {
+	return m.withLock(ctx, m.complete)
+}
+
+func (m *PgxMigrator) complete(ctx context.Context) error {
+	version, _, schemaName, err := m.activeMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	versioned, ok := m.migrations[version].(VersionedMigration)
+	if !ok {
+		return fmt.Errorf("active migration %s does not implement VersionedMigration", version)
+	}
+
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, op := range versioned.Operations() {
+		var sql string
+		switch o := op.(type) {
+		case DropColumn:
+			sql = fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", o.Table, o.Column)
+		case RenameColumn:
+			sql = fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", o.Table, o.From, o.To)
+		case SetNotNull:
+			sql = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", o.Table, o.Column)
+		default:
+			continue
+		}
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to finalize %T on %s: %w", op, versioned.Table(), err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s.%s", schemaName, versioned.Table())); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to drop versioned view: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE pgroll_migrations SET status = $2, completed_at = $3 WHERE version = $1",
+		version, pgrollCompleted, time.Now(),
+	); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to mark migration %s completed: %w", version, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Rollback abandons the active versioned migration: it drops the new
+// versioned schema without touching the physical table, and marks the
+// migration rolled back.
+func (m *PgxMigrator) Rollback(ctx context.Context) error {
+	return m.withLock(ctx, m.rollback)
+}
+
+func (m *PgxMigrator) rollback(ctx context.Context) error {
+	version, _, schemaName, err := m.activeMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schemaName)); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to drop versioned schema %s: %w", schemaName, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE pgroll_migrations SET status = $2 WHERE version = $1",
+		version, pgrollRolledBack,
+	); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to mark migration %s rolled back: %w", version, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ActiveSchema returns the versioned schema name of the currently active
+// expand/contract migration, or "" if none is active.
+func (m *PgxMigrator) ActiveSchema(ctx context.Context) (string, error) {
+	if err := m.createPgrollTable(ctx); err != nil {
+		return "", fmt.Errorf("failed to create pgroll_migrations table: %w", err)
+	}
+
+	var schemaName string
+	err := m.pool.QueryRow(ctx, "SELECT schema_name FROM pgroll_migrations WHERE status = 'active' LIMIT 1").Scan(&schemaName)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query active schema: %w", err)
+	}
+
+	return schemaName, nil
+}